@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNewTaskWorker_UsesEmbeddedABIByDefault(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	worker := NewTaskWorker(logger, &Config{})
+
+	if _, ok := worker.contract.Load().abi.Methods[issuePaymentCertMethod]; !ok {
+		t.Fatalf("expected embedded ABI to expose %q", issuePaymentCertMethod)
+	}
+}
+
+func TestNewTaskWorker_ABIPathOverridesEmbeddedDefault(t *testing.T) {
+	customABI := `[{"name": "core_issuePaymentCert", "type": "function", "inputs": [{"name": "txHash", "type": "bytes32"}, {"name": "note", "type": "string"}]}]`
+	abiPath := filepath.Join(t.TempDir(), "custom.json")
+	if err := os.WriteFile(abiPath, []byte(customABI), 0o644); err != nil {
+		t.Fatalf("failed to write custom ABI file: %v", err)
+	}
+
+	logger, _ := zap.NewDevelopment()
+	worker := NewTaskWorker(logger, &Config{ABIPath: abiPath})
+
+	method, ok := worker.contract.Load().abi.Methods[issuePaymentCertMethod]
+	if !ok {
+		t.Fatalf("expected custom ABI to expose %q", issuePaymentCertMethod)
+	}
+	if len(method.Inputs) != 2 {
+		t.Errorf("expected custom ABI method to have 2 inputs, got %d", len(method.Inputs))
+	}
+}
+
+func TestTaskWorker_RegisterABI_ReplacesCachedABIAndRegistry(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	worker := NewTaskWorker(logger, &Config{})
+
+	customABI, err := loadContractABI("")
+	if err != nil {
+		t.Fatalf("failed to load contract ABI: %v", err)
+	}
+
+	if err := worker.RegisterABI(customABI); err != nil {
+		t.Fatalf("RegisterABI failed: %v", err)
+	}
+
+	cached, err := worker.getParsedABI()
+	if err != nil {
+		t.Fatalf("getParsedABI failed: %v", err)
+	}
+	if _, ok := cached.Methods[issuePaymentCertMethod]; !ok {
+		t.Fatalf("expected re-registered ABI to expose %q", issuePaymentCertMethod)
+	}
+}
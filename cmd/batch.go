@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/4mica-Network/4mica-core-avs/jsonrpc"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+)
+
+// taskOutcome is one task's result from handleDecodedTasks: either a
+// TaskResponse or the error that prevented producing one, never both.
+type taskOutcome struct {
+	resp *performerV1.TaskResponse
+	err  error
+}
+
+// HandleTaskBatch decodes tasks and forwards the ones whose method
+// supports batching as a single JSON-RPC 2.0 batch request per
+// config.MaxBatchSize chunk, cutting the number of upstream round trips
+// when many payment certs are issued concurrently. Tasks whose method has
+// no registered BatchParamsFunc are handled individually through the
+// normal HandleTask path. The returned slice is always len(tasks) long and
+// positionally aligned with it; a task that fails to decode or call gets a
+// JSON-RPC error envelope in its Result instead of aborting the batch.
+func (tw *TaskWorker) HandleTaskBatch(tasks []*performerV1.TaskRequest) ([]*performerV1.TaskResponse, error) {
+	starts := make([]time.Time, len(tasks))
+	for i := range tasks {
+		starts[i] = time.Now()
+	}
+
+	outcomes := tw.handleDecodedTasks(tasks, starts)
+
+	responses := make([]*performerV1.TaskResponse, len(outcomes))
+	for i, outcome := range outcomes {
+		if outcome.err != nil {
+			responses[i] = errorTaskResponse(tasks[i].TaskId, outcome.err)
+			continue
+		}
+		responses[i] = outcome.resp
+	}
+	return responses, nil
+}
+
+// handleDecodedTasks is the shared core behind HandleTaskBatch and
+// BatchCollector: it decodes each task, forwards the ones whose method
+// supports batching as a single JSON-RPC 2.0 batch request per
+// config.MaxBatchSize chunk, and runs the rest individually. Unlike
+// HandleTaskBatch, a per-task failure here is returned as a real Go error
+// in that task's outcome rather than a JSON-RPC error envelope, so
+// BatchCollector (and, through it, HandleTask) can propagate the original
+// error the same way a non-batched call would. starts holds each task's
+// own "clock start" for TaskDuration (index-aligned with tasks); callers
+// that buffer tasks before reaching this function, like BatchCollector,
+// should pass each task's enqueue time rather than time.Now(), so the
+// histogram reflects full caller-observed latency instead of just the
+// time since the batch was dequeued. The oldest of starts also sets the
+// batch's deadline, so buffering time counts against config.TaskTimeout.
+func (tw *TaskWorker) handleDecodedTasks(tasks []*performerV1.TaskRequest, starts []time.Time) []taskOutcome {
+	ctx, cancel := tw.taskContext()
+	defer cancel()
+
+	oldest := time.Now()
+	for _, s := range starts {
+		if s.Before(oldest) {
+			oldest = s
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancelOldest context.CancelFunc
+		ctx, cancelOldest = context.WithDeadline(ctx, deadline.Add(-time.Since(oldest)))
+		defer cancelOldest()
+	}
+
+	tw.metrics.InFlightTasks.Add(float64(len(tasks)))
+	defer tw.metrics.InFlightTasks.Sub(float64(len(tasks)))
+
+	outcomes := make([]taskOutcome, len(tasks))
+	methodNames := make([]string, len(tasks))
+
+	type batchable struct {
+		taskIndex int
+		taskID    []byte
+		rpcMethod string
+		params    interface{}
+	}
+	var batch []batchable
+
+	for i, t := range tasks {
+		call, err := tw.contract.Load().registry.decode(t.Payload)
+		if err != nil {
+			outcomes[i] = taskOutcome{err: fmt.Errorf("failed to decode task payload: %w", err)}
+			methodNames[i] = "unknown"
+			tw.recordTaskMetrics(methodNames[i], starts[i], outcomes[i].err)
+			continue
+		}
+		methodNames[i] = call.entry.abiMethod.Name
+
+		if call.entry.batchParams == nil {
+			resp, err := call.entry.handler(ctx, tw, t.TaskId, call.args)
+			outcomes[i] = taskOutcome{resp: resp, err: err}
+			tw.recordTaskMetrics(methodNames[i], starts[i], err)
+			continue
+		}
+
+		rpcMethod, params, err := call.entry.batchParams(call.args)
+		if err != nil {
+			outcomes[i] = taskOutcome{err: err}
+			tw.recordTaskMetrics(methodNames[i], starts[i], err)
+			continue
+		}
+		batch = append(batch, batchable{taskIndex: i, taskID: t.TaskId, rpcMethod: rpcMethod, params: params})
+	}
+
+	for chunkStart := 0; chunkStart < len(batch); chunkStart += tw.config.MaxBatchSize {
+		chunkEnd := chunkStart + tw.config.MaxBatchSize
+		if chunkEnd > len(batch) {
+			chunkEnd = len(batch)
+		}
+		chunk := batch[chunkStart:chunkEnd]
+
+		calls := make([]jsonrpc.BatchCall, len(chunk))
+		for i, b := range chunk {
+			calls[i] = jsonrpc.BatchCall{Method: b.rpcMethod, Params: b.params}
+		}
+
+		rpcResponses, err := tw.callUpstreamBatch(ctx, calls)
+		if err != nil {
+			for _, b := range chunk {
+				outcomes[b.taskIndex] = taskOutcome{err: fmt.Errorf("batch call failed: %w", err)}
+				tw.recordTaskMetrics(methodNames[b.taskIndex], starts[b.taskIndex], outcomes[b.taskIndex].err)
+			}
+			continue
+		}
+
+		for i, b := range chunk {
+			rpcResp := rpcResponses[i]
+			switch {
+			case rpcResp == nil:
+				outcomes[b.taskIndex] = taskOutcome{err: fmt.Errorf("no response for %q in batch", b.rpcMethod)}
+			case rpcResp.Error != nil:
+				outcomes[b.taskIndex] = taskOutcome{err: rpcResp.Error}
+			default:
+				body, err := json.Marshal(rpcResp)
+				if err != nil {
+					outcomes[b.taskIndex] = taskOutcome{err: fmt.Errorf("failed to encode response for %q: %w", b.rpcMethod, err)}
+					break
+				}
+				outcomes[b.taskIndex] = taskOutcome{resp: &performerV1.TaskResponse{TaskId: b.taskID, Result: body}}
+			}
+			tw.recordTaskMetrics(methodNames[b.taskIndex], starts[b.taskIndex], outcomes[b.taskIndex].err)
+		}
+	}
+
+	return outcomes
+}
+
+// recordTaskMetrics observes a single task's own latency (measured from
+// that task's start, its enqueue time when it came through BatchCollector,
+// not the whole batch's wall-clock time) and tallies its outcome,
+// mirroring HandleTask's per-task instrumentation.
+func (tw *TaskWorker) recordTaskMetrics(methodName string, start time.Time, err error) {
+	tw.metrics.TaskDuration.WithLabelValues(methodName).Observe(time.Since(start).Seconds())
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	tw.metrics.TasksHandled.WithLabelValues(methodName, status).Inc()
+}
+
+// errorTaskResponse wraps err as a JSON-RPC 2.0 error envelope carried in a
+// TaskResponse's Result, so a single failed task doesn't need to abort an
+// entire batch.
+func errorTaskResponse(taskID []byte, err error) *performerV1.TaskResponse {
+	body, marshalErr := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error": map[string]interface{}{
+			"code":    -32000,
+			"message": err.Error(),
+		},
+	})
+	if marshalErr != nil {
+		body = []byte(fmt.Sprintf(`{"jsonrpc":"2.0","error":{"code":-32000,"message":%q}}`, err.Error()))
+	}
+	return &performerV1.TaskResponse{TaskId: taskID, Result: body}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+)
+
+func packIssuePaymentCert(t *testing.T, worker *TaskWorker, seed string) []byte {
+	t.Helper()
+	parsedABI, err := worker.getParsedABI()
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+
+	var txHash [32]byte
+	copy(txHash[:], []byte(seed))
+
+	packed, err := parsedABI.Pack(issuePaymentCertMethod, txHash)
+	if err != nil {
+		t.Fatalf("failed to pack payload: %v", err)
+	}
+	return packed
+}
+
+func TestHandleTaskBatch_CoalescesIntoSingleRPCCall(t *testing.T) {
+	var batchRequests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batchRequests++
+
+		var reqs []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatalf("expected a JSON-RPC batch array, got decode error: %v", err)
+		}
+
+		resps := make([]map[string]interface{}, len(reqs))
+		for i, req := range reqs {
+			resps[i] = map[string]interface{}{
+				"jsonrpc": "2.0",
+				"result":  req["params"].([]interface{})[0],
+				"id":      req["id"],
+			}
+		}
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer srv.Close()
+
+	worker := newTestWorker(t, srv.URL)
+
+	tasks := []*performerV1.TaskRequest{
+		{TaskId: []byte("task-1"), Payload: packIssuePaymentCert(t, worker, "task-1-hash-is-32-bytes-long!!!")},
+		{TaskId: []byte("task-2"), Payload: packIssuePaymentCert(t, worker, "task-2-hash-is-32-bytes-long!!!")},
+	}
+
+	responses, err := worker.HandleTaskBatch(tasks)
+	if err != nil {
+		t.Fatalf("HandleTaskBatch failed: %v", err)
+	}
+	if len(responses) != len(tasks) {
+		t.Fatalf("unexpected response count: got %d, want %d", len(responses), len(tasks))
+	}
+	for i, resp := range responses {
+		if resp == nil {
+			t.Fatalf("response %d is nil", i)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(resp.Result, &decoded); err != nil {
+			t.Fatalf("failed to unmarshal response %d: %v", i, err)
+		}
+		if _, hasError := decoded["error"]; hasError {
+			t.Errorf("response %d returned an error: %v", i, decoded["error"])
+		}
+	}
+	if batchRequests != 1 {
+		t.Errorf("expected a single coalesced HTTP request, got %d", batchRequests)
+	}
+}
+
+func TestHandleTaskBatch_PerTaskErrorDoesNotAbortBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqs)
+		resps := make([]map[string]interface{}, len(reqs))
+		for i, req := range reqs {
+			resps[i] = map[string]interface{}{"jsonrpc": "2.0", "result": "ok", "id": req["id"]}
+		}
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer srv.Close()
+
+	worker := newTestWorker(t, srv.URL)
+
+	tasks := []*performerV1.TaskRequest{
+		{TaskId: []byte("bad-task"), Payload: []byte{0x01, 0x02}},
+		{TaskId: []byte("good-task"), Payload: packIssuePaymentCert(t, worker, "good-task-hash-is-32-bytes-long!")},
+	}
+
+	responses, err := worker.HandleTaskBatch(tasks)
+	if err != nil {
+		t.Fatalf("HandleTaskBatch failed: %v", err)
+	}
+
+	var badResp map[string]interface{}
+	if err := json.Unmarshal(responses[0].Result, &badResp); err != nil {
+		t.Fatalf("failed to unmarshal bad task response: %v", err)
+	}
+	if _, hasError := badResp["error"]; !hasError {
+		t.Errorf("expected an error envelope for the malformed task, got %s", responses[0].Result)
+	}
+
+	var goodResp map[string]interface{}
+	if err := json.Unmarshal(responses[1].Result, &goodResp); err != nil {
+		t.Fatalf("failed to unmarshal good task response: %v", err)
+	}
+	if _, hasError := goodResp["error"]; hasError {
+		t.Errorf("expected the well-formed task to succeed, got %s", responses[1].Result)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+)
+
+// taskResult is the outcome of a single task handled as part of a batch,
+// delivered back to the goroutine that submitted it to the BatchCollector.
+type taskResult struct {
+	resp *performerV1.TaskResponse
+	err  error
+}
+
+// pendingTask is a task waiting in the BatchCollector's buffer for the next
+// flush. enqueuedAt is captured in Submit, before any buffering delay, so
+// TaskDuration reflects the full time the caller actually waited.
+type pendingTask struct {
+	task       *performerV1.TaskRequest
+	enqueuedAt time.Time
+	result     chan<- taskResult
+}
+
+// BatchCollector buffers tasks that arrive one at a time through HandleTask
+// for up to config.BatchWindow, or until config.MaxBatchSize accumulate,
+// whichever comes first, then flushes them together through
+// TaskWorker.handleDecodedTasks, the batching core shared with
+// HandleTaskBatch. This is what lets batchable methods (e.g.
+// core_issuePaymentCert) benefit from a single coalesced upstream JSON-RPC
+// call even though the performer hands tasks to HandleTask individually.
+type BatchCollector struct {
+	tw      *TaskWorker
+	window  time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	pending []pendingTask
+	timer   *time.Timer
+}
+
+// NewBatchCollector builds a BatchCollector that flushes through tw.
+func NewBatchCollector(tw *TaskWorker) *BatchCollector {
+	return &BatchCollector{
+		tw:      tw,
+		window:  tw.config.BatchWindow,
+		maxSize: tw.config.MaxBatchSize,
+	}
+}
+
+// Submit enqueues t and blocks until it has been flushed, individually or
+// as part of a batch, and its result is known.
+func (c *BatchCollector) Submit(t *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	resultCh := make(chan taskResult, 1)
+
+	c.mu.Lock()
+	c.pending = append(c.pending, pendingTask{task: t, enqueuedAt: time.Now(), result: resultCh})
+	switch {
+	case len(c.pending) >= c.maxSize:
+		c.flushLocked()
+	case c.timer == nil:
+		c.timer = time.AfterFunc(c.window, c.flush)
+	}
+	c.mu.Unlock()
+
+	res := <-resultCh
+	return res.resp, res.err
+}
+
+func (c *BatchCollector) flush() {
+	c.mu.Lock()
+	c.flushLocked()
+	c.mu.Unlock()
+}
+
+// flushLocked drains the pending buffer and hands it to handleDecodedTasks
+// on a separate goroutine, so it doesn't hold c.mu (and therefore block new
+// Submit calls) for the duration of the upstream call. Callers must hold
+// c.mu.
+func (c *BatchCollector) flushLocked() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if len(c.pending) == 0 {
+		return
+	}
+
+	batch := c.pending
+	c.pending = nil
+
+	tasks := make([]*performerV1.TaskRequest, len(batch))
+	starts := make([]time.Time, len(batch))
+	for i, p := range batch {
+		tasks[i] = p.task
+		starts[i] = p.enqueuedAt
+	}
+
+	go func() {
+		outcomes := c.tw.handleDecodedTasks(tasks, starts)
+		for i, p := range batch {
+			p.result <- taskResult{resp: outcomes[i].resp, err: outcomes[i].err}
+		}
+	}()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+)
+
+func TestHandleTask_CoalescesConcurrentTasksWithinBatchWindow(t *testing.T) {
+	var batchRequests int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		batchRequests++
+		mu.Unlock()
+
+		var reqs []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatalf("expected a JSON-RPC batch array, got decode error: %v", err)
+		}
+
+		resps := make([]map[string]interface{}, len(reqs))
+		for i, req := range reqs {
+			resps[i] = map[string]interface{}{
+				"jsonrpc": "2.0",
+				"result":  req["params"].([]interface{})[0],
+				"id":      req["id"],
+			}
+		}
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer srv.Close()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	worker := NewTaskWorker(logger, &Config{
+		RPCServerURL: srv.URL,
+		BatchWindow:  50 * time.Millisecond,
+	})
+
+	tasks := []*performerV1.TaskRequest{
+		{TaskId: []byte("task-1"), Payload: packIssuePaymentCert(t, worker, "task-1-hash-is-32-bytes-long!!!")},
+		{TaskId: []byte("task-2"), Payload: packIssuePaymentCert(t, worker, "task-2-hash-is-32-bytes-long!!!")},
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]*performerV1.TaskResponse, len(tasks))
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task *performerV1.TaskRequest) {
+			defer wg.Done()
+			responses[i], errs[i] = worker.HandleTask(task)
+		}(i, task)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("HandleTask %d failed: %v", i, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if batchRequests != 1 {
+		t.Errorf("expected concurrently-submitted tasks to coalesce into a single batch request, got %d", batchRequests)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/4mica-Network/4mica-core-avs/jsonrpc"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+)
+
+func newTestWorkerWithTimeout(t *testing.T, rpcServerURL string, taskTimeout time.Duration) *TaskWorker {
+	t.Helper()
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	return NewTaskWorker(logger, &Config{
+		RPCServerURL:        rpcServerURL,
+		TaskTimeout:         taskTimeout,
+		ResponseWriteMargin: taskTimeout / 4,
+	})
+}
+
+// TestHandleTask_SlowUpstreamReturnsDeadlineExceeded simulates an upstream
+// RPC server that never responds within the task's deadline and asserts
+// HandleTask gives up promptly with a structured error rather than
+// hanging until the HTTP client's own timeout (or the caller) kills it.
+func TestHandleTask_SlowUpstreamReturnsDeadlineExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "result": "ok", "id": 1})
+	}))
+	defer srv.Close()
+
+	worker := newTestWorkerWithTimeout(t, srv.URL, 50*time.Millisecond)
+
+	parsedABI, err := worker.getParsedABI()
+	if err != nil {
+		t.Fatalf("Failed to parse ABI: %v", err)
+	}
+	var txHash [32]byte
+	copy(txHash[:], []byte("slow-upstream-hash-is-32-bytes!"))
+	packed, err := parsedABI.Pack(issuePaymentCertMethod, txHash)
+	if err != nil {
+		t.Fatalf("Failed to pack payload: %v", err)
+	}
+
+	req := &performerV1.TaskRequest{TaskId: []byte("slow-task"), Payload: packed}
+
+	start := time.Now()
+	_, err = worker.HandleTask(req)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected a deadline-exceeded error, got nil")
+	}
+	var rpcErr *jsonrpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected *jsonrpc.RPCError, got %T: %v", err, err)
+	}
+	if rpcErr.Code != jsonrpc.CodeDeadlineExceeded {
+		t.Errorf("unexpected error code: got %d, want %d", rpcErr.Code, jsonrpc.CodeDeadlineExceeded)
+	}
+
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("HandleTask took too long to give up: %v", elapsed)
+	}
+}
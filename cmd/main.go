@@ -3,190 +3,367 @@ package main
 import (
 	"bytes"
 	"context"
+	_ "embed"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
+	"os"
+	"sync/atomic"
 	"time"
 
+	"github.com/4mica-Network/4mica-core-avs/jsonrpc"
+	"github.com/4mica-Network/4mica-core-avs/metrics"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/server"
 	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"go.uber.org/zap"
 )
 
+//go:embed contractabi/payment_cert.json
+var defaultContractABI []byte
+
 type Config struct {
 	RPCServerURL string
+
+	// ABIPath optionally overrides the embedded default contract ABI with
+	// one loaded from disk, so the same binary can be pointed at a
+	// different contract without a rebuild.
+	ABIPath string
+
+	// RPCRequestTimeout bounds a single outbound HTTP attempt of a JSON-RPC
+	// call; each retry gets its own timeout. Defaults to 10s when zero.
+	RPCRequestTimeout time.Duration
+
+	// RPCDialTimeout bounds establishing the connection to the upstream
+	// RPC server. Defaults to 5s when zero.
+	RPCDialTimeout time.Duration
+
+	// RPCMaxRetries is the number of retries attempted for idempotent
+	// JSON-RPC calls on 5xx responses or network errors.
+	RPCMaxRetries int
+
+	// BatchWindow is how long TaskWorker's BatchCollector buffers
+	// individually-arriving tasks (as delivered one at a time through
+	// HandleTask) before flushing them together through handleDecodedTasks
+	// (the batching core shared with HandleTaskBatch) as one JSON-RPC batch
+	// request. A flush also happens early once
+	// MaxBatchSize tasks have accumulated. Defaults to 25ms.
+	BatchWindow time.Duration
+
+	// MaxBatchSize caps how many tasks are sent in a single JSON-RPC
+	// batch request; HandleTaskBatch splits larger slices into multiple
+	// requests. Defaults to 50.
+	MaxBatchSize int
+
+	// TaskTimeout bounds how long ValidateTask/HandleTask may take end to
+	// end. It should match (or be slightly under) the Timeout given to
+	// server.PonosPerformerConfig, since that is the hard deadline the
+	// surrounding Ponos performer enforces on the HTTP handler. Defaults
+	// to 5s.
+	TaskTimeout time.Duration
+
+	// ResponseWriteMargin is reserved out of TaskTimeout for building and
+	// returning the TaskResponse once the outbound RPC call completes, so
+	// the outbound call is cancelled with enough time left to return a
+	// structured error instead of being killed mid-flush by the
+	// performer's own timeout. Defaults to 200ms.
+	//
+	// Note: this only bounds how long TaskWorker itself takes to produce a
+	// TaskResponse. The HTTP response that carries it is written by
+	// server.PonosPerformerConfig's RPC server, which this package doesn't
+	// own, so setting Content-Length and disabling chunked transfer
+	// encoding on the timeout path (to guarantee a partial body is never
+	// flushed) has to happen in that server, not here. Descoped until
+	// Ponos exposes a hook to configure its ResponseWriter.
+	ResponseWriteMargin time.Duration
+
+	// MetricsNamespace prefixes every exported Prometheus series name.
+	// Defaults to "core_avs_performer".
+	MetricsNamespace string
+
+	// RedactRPCParams replaces the "params" field of debug-level upstream
+	// RPC logs with "[redacted]", for deployments where payloads may carry
+	// sensitive data.
+	RedactRPCParams bool
+
+	// MetricsAddr, if set, is the address MetricsHandler is served on (e.g.
+	// ":9090") via a dedicated HTTP server started alongside the performer.
+	// Left empty, no metrics server is started.
+	MetricsAddr string
+}
+
+const (
+	defaultBatchWindow         = 25 * time.Millisecond
+	defaultMaxBatchSize        = 50
+	defaultTaskTimeout         = 5 * time.Second
+	defaultResponseWriteMargin = 200 * time.Millisecond
+	defaultMetricsNamespace    = "core_avs_performer"
+)
+
+// contractBinding bundles the parsed ABI and the MethodRegistry built
+// against it, so RegisterABI can swap both atomically: a reader must never
+// observe a registry built from one ABI paired with another.
+type contractBinding struct {
+	abi      abi.ABI
+	registry *MethodRegistry
 }
 
 type TaskWorker struct {
-	logger *zap.Logger
-	config *Config
+	logger    *zap.Logger
+	config    *Config
+	rpcClient *jsonrpc.Client
+	contract  atomic.Pointer[contractBinding]
+	metrics   *metrics.Metrics
+	collector *BatchCollector
 }
 
 func NewTaskWorker(logger *zap.Logger, config *Config) *TaskWorker {
-	return &TaskWorker{
-		logger: logger,
-		config: config,
+	if config.BatchWindow <= 0 {
+		config.BatchWindow = defaultBatchWindow
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaultMaxBatchSize
+	}
+	if config.TaskTimeout <= 0 {
+		config.TaskTimeout = defaultTaskTimeout
+	}
+	if config.ResponseWriteMargin <= 0 {
+		config.ResponseWriteMargin = defaultResponseWriteMargin
+	}
+	if config.MetricsNamespace == "" {
+		config.MetricsNamespace = defaultMetricsNamespace
 	}
-}
 
-func (tw *TaskWorker) ValidateTask(t *performerV1.TaskRequest) error {
-	logger := tw.logger.Sugar()
-	tw.logger.Sugar().Infow("Validating task", zap.Any("task", t))
-	const (
-		jsonRPCVersion       = "2.0"
-		jsonContentType      = "application/json"
-		jsonRPCMethodName    = "core_issuePaymentCert"
-		expectedArgCount     = 1
-		expectedArgTypeSize  = 32
-		methodSelectorLength = 4
-	)
+	rpcClient := jsonrpc.NewClient(&jsonrpc.Config{
+		URL:            config.RPCServerURL,
+		RequestTimeout: config.RPCRequestTimeout,
+		DialTimeout:    config.RPCDialTimeout,
+		MaxRetries:     config.RPCMaxRetries,
+	})
+
+	tw := &TaskWorker{
+		logger:    logger,
+		config:    config,
+		rpcClient: rpcClient,
+		metrics:   metrics.New(config.MetricsNamespace),
+	}
+	tw.collector = NewBatchCollector(tw)
 
-	parsedABI, err := tw.getParsedABI()
+	parsedABI, err := loadContractABI(config.ABIPath)
 	if err != nil {
-		logger.Errorw("Failed to parse ABI", "error", err)
-		return fmt.Errorf("failed to parse ABI: %w", err)
+		// The ABI is resolved once at startup; a parse failure here means
+		// the binary or its configured ABIPath is broken, not a runtime
+		// condition worth degrading gracefully for.
+		logger.Sugar().Fatalw("Failed to load contract ABI", "abi_path", config.ABIPath, "error", err)
 	}
 
-	method, ok := parsedABI.Methods["dummy"]
-	if !ok {
-		return fmt.Errorf("ABI method 'dummy' not found")
+	if err := tw.RegisterABI(parsedABI); err != nil {
+		logger.Sugar().Fatalw("Failed to register contract ABI", "error", err)
 	}
 
-	if len(t.Payload) < methodSelectorLength {
-		logger.Errorw("Payload too short", "min_required", methodSelectorLength, "actual", len(t.Payload))
-		return fmt.Errorf("payload too short: expected at least %d bytes", methodSelectorLength)
+	return tw
+}
+
+// loadContractABI parses the contract ABI from abiPath if set, otherwise
+// falls back to the embedded default shipped with the binary.
+func loadContractABI(abiPath string) (abi.ABI, error) {
+	raw := defaultContractABI
+	if abiPath != "" {
+		fileBytes, err := os.ReadFile(abiPath)
+		if err != nil {
+			return abi.ABI{}, fmt.Errorf("failed to read ABI file %q: %w", abiPath, err)
+		}
+		raw = fileBytes
 	}
 
-	args, err := method.Inputs.Unpack(t.Payload[methodSelectorLength:])
+	parsedABI, err := abi.JSON(bytes.NewReader(raw))
 	if err != nil {
-		logger.Errorw("Failed to unpack method arguments", "error", err)
-		return fmt.Errorf("failed to unpack method arguments: %w", err)
+		return abi.ABI{}, fmt.Errorf("failed to parse ABI: %w", err)
 	}
+	return parsedABI, nil
+}
 
-	if len(args) != expectedArgCount {
-		logger.Errorw("Unexpected number of arguments", "expected", expectedArgCount, "actual", len(args))
-		return fmt.Errorf("unexpected number of arguments: expected %d, got %d", expectedArgCount, len(args))
+// RegisterABI replaces the TaskWorker's cached ABI and rebuilds its
+// MethodRegistry against it, re-registering the built-in handlers. This
+// lets operators point the same binary at a different contract without a
+// rebuild, by calling RegisterABI with a freshly parsed ABI. The swap is
+// published atomically so ValidateTask/HandleTask/HandleTaskBatch, which
+// may be running concurrently against the performer's in-flight requests,
+// always see a consistent abi/registry pair.
+func (tw *TaskWorker) RegisterABI(parsedABI abi.ABI) error {
+	registry := NewMethodRegistry(parsedABI)
+	if err := registry.Register(issuePaymentCertMethod, handleIssuePaymentCert); err != nil {
+		return fmt.Errorf("failed to register %q handler: %w", issuePaymentCertMethod, err)
+	}
+	if err := registry.RegisterBatchParams(issuePaymentCertMethod, buildIssuePaymentCertParams); err != nil {
+		return fmt.Errorf("failed to register %q batch params: %w", issuePaymentCertMethod, err)
 	}
 
+	tw.contract.Store(&contractBinding{abi: parsedABI, registry: registry})
 	return nil
 }
 
-func (tw *TaskWorker) HandleTask(t *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
-	const (
-		jsonRPCVersion       = "2.0"
-		jsonContentType      = "application/json"
-		jsonRPCMethodName    = "core_issuePaymentCert"
-		expectedArgCount     = 1
-		expectedArgTypeSize  = 32
-		methodSelectorLength = 4
-	)
+// taskContext derives a context bounded by the performer's deadline
+// (config.TaskTimeout), reserving config.ResponseWriteMargin so an
+// outbound RPC call is cancelled with enough time left to build and
+// return a structured error instead of being cut off mid-flush once the
+// performer's own timeout fires.
+func (tw *TaskWorker) taskContext() (context.Context, context.CancelFunc) {
+	budget := tw.config.TaskTimeout - tw.config.ResponseWriteMargin
+	if budget <= 0 {
+		budget = tw.config.TaskTimeout
+	}
+	return context.WithTimeout(context.Background(), budget)
+}
+
+func (tw *TaskWorker) ValidateTask(t *performerV1.TaskRequest) error {
+	logger := tw.logger.Sugar()
+	logger.Infow("Validating task", zap.Any("task", t))
+
+	if _, err := tw.contract.Load().registry.decode(t.Payload); err != nil {
+		logger.Errorw("Failed to decode task payload", "error", err)
+		return fmt.Errorf("failed to decode task payload: %w", err)
+	}
 
+	return nil
+}
+
+func (tw *TaskWorker) HandleTask(t *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
 	logger := tw.logger.Sugar()
 	logger.Infow("Handling task", "task_id", t.TaskId, "payload_length", len(t.Payload))
 
-	parsedABI, err := tw.getParsedABI()
+	call, err := tw.contract.Load().registry.decode(t.Payload)
 	if err != nil {
-		logger.Errorw("Failed to parse ABI", "error", err)
-		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+		logger.Errorw("Failed to decode task payload", "error", err)
+		return nil, fmt.Errorf("failed to decode task payload: %w", err)
 	}
 
-	method, ok := parsedABI.Methods[jsonRPCMethodName]
-	if !ok {
-		logger.Errorw("ABI method not found", "method", jsonRPCMethodName)
-		return nil, fmt.Errorf("ABI method '%s' not found", jsonRPCMethodName)
+	// Methods that support batching are handed to the BatchCollector so
+	// tasks arriving one at a time within config.BatchWindow of each other
+	// still get coalesced into a single upstream JSON-RPC batch call.
+	if call.entry.batchParams != nil {
+		return tw.collector.Submit(t)
 	}
 
-	if len(t.Payload) < methodSelectorLength {
-		logger.Errorw("Payload too short", "min_required", methodSelectorLength, "actual", len(t.Payload))
-		return nil, fmt.Errorf("payload too short: expected at least %d bytes", methodSelectorLength)
-	}
+	methodName := call.entry.abiMethod.Name
+
+	tw.metrics.InFlightTasks.Inc()
+	defer tw.metrics.InFlightTasks.Dec()
+
+	ctx, cancel := tw.taskContext()
+	defer cancel()
+
+	start := time.Now()
+	resp, err := call.entry.handler(ctx, tw, t.TaskId, call.args)
+	tw.metrics.TaskDuration.WithLabelValues(methodName).Observe(time.Since(start).Seconds())
 
-	args, err := method.Inputs.Unpack(t.Payload[methodSelectorLength:])
+	status := "success"
 	if err != nil {
-		logger.Errorw("Failed to unpack method arguments", "error", err)
-		return nil, fmt.Errorf("failed to unpack method arguments: %w", err)
+		status = "failure"
 	}
+	tw.metrics.TasksHandled.WithLabelValues(methodName, status).Inc()
+
+	return resp, err
+}
+
+// MetricsHandler serves Prometheus metrics for this TaskWorker in the text
+// exposition format, suitable for mounting at /metrics.
+func (tw *TaskWorker) MetricsHandler() http.Handler {
+	return tw.metrics.Handler()
+}
+
+const issuePaymentCertMethod = "core_issuePaymentCert"
+
+// buildIssuePaymentCertParams converts the decoded arguments of
+// "core_issuePaymentCert" into the upstream JSON-RPC params. It is shared
+// by the single-task handler and the batch path so both encode the call
+// identically.
+func buildIssuePaymentCertParams(args []interface{}) (string, interface{}, error) {
+	const (
+		expectedArgCount    = 1
+		expectedArgTypeSize = 32
+	)
 
 	if len(args) != expectedArgCount {
-		logger.Errorw("Unexpected number of arguments", "expected", expectedArgCount, "actual", len(args))
-		return nil, fmt.Errorf("unexpected number of arguments: expected %d, got %d", expectedArgCount, len(args))
+		return "", nil, fmt.Errorf("unexpected number of arguments: expected %d, got %d", expectedArgCount, len(args))
 	}
 
 	txHashArg := args[0]
 	txHash, valid := txHashArg.([expectedArgTypeSize]byte)
 	if !valid {
-		logger.Errorw("Invalid argument type for txHash", "expected", fmt.Sprintf("[%d]byte", expectedArgTypeSize), "actual", fmt.Sprintf("%T", txHashArg))
-		return nil, fmt.Errorf("unexpected argument type: expected [%d]byte, got %T", expectedArgTypeSize, txHashArg)
+		return "", nil, fmt.Errorf("unexpected argument type: expected [%d]byte, got %T", expectedArgTypeSize, txHashArg)
 	}
 
-	jsonPayload := map[string]interface{}{
-		"jsonrpc": jsonRPCVersion,
-		"method":  jsonRPCMethodName,
-		"params":  []interface{}{fmt.Sprintf("0x%x", txHash)},
-		"id":      1,
-	}
+	return issuePaymentCertMethod, []interface{}{fmt.Sprintf("0x%x", txHash)}, nil
+}
 
-	jsonData, err := json.Marshal(jsonPayload)
+// handleIssuePaymentCert is the built-in handler for the
+// "core_issuePaymentCert" ABI method: it forwards the decoded txHash to the
+// upstream RPC server and returns its response verbatim.
+func handleIssuePaymentCert(ctx context.Context, tw *TaskWorker, taskID []byte, args []interface{}) (*performerV1.TaskResponse, error) {
+	logger := tw.logger.Sugar()
+
+	rpcMethod, params, err := buildIssuePaymentCertParams(args)
 	if err != nil {
-		logger.Errorw("Failed to marshal JSON payload", "error", err)
-		return nil, fmt.Errorf("failed to marshal JSON-RPC payload: %w", err)
+		logger.Errorw("Failed to build RPC params", "method", issuePaymentCertMethod, "error", err)
+		return nil, err
 	}
 
-	resp, err := http.Post(tw.config.RPCServerURL, jsonContentType, bytes.NewBuffer(jsonData))
+	resp, err := tw.callUpstream(ctx, taskID, rpcMethod, params, false)
 	if err != nil {
-		logger.Errorw("HTTP request failed", "url", tw.config.RPCServerURL, "error", err)
-		return nil, fmt.Errorf("failed to send POST request: %w", err)
+		logger.Errorw("JSON-RPC call failed", "url", tw.config.RPCServerURL, "method", rpcMethod, "error", err)
+		return nil, fmt.Errorf("failed to call %q: %w", rpcMethod, err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	logger.Infow("Received response from RPC server", "method", rpcMethod, "result", string(resp.Result))
+
+	body, err := json.Marshal(resp)
 	if err != nil {
-		logger.Errorw("Failed to read response body", "error", err)
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to encode %q response: %w", rpcMethod, err)
 	}
 
-	logger.Infow("Received response from RPC server", "status_code", resp.StatusCode, "body", string(body))
-
 	return &performerV1.TaskResponse{
-		TaskId: t.TaskId,
+		TaskId: taskID,
 		Result: body,
 	}, nil
 }
 
-// getParsedABI returns the parsed ABI for the contract. Consider caching this at init.
+// getParsedABI returns the ABI cached on tw at construction (or by the most
+// recent RegisterABI call), rather than re-parsing it on every call.
 func (tw *TaskWorker) getParsedABI() (abi.ABI, error) {
-	const contractABI = `
-	[{
-		"name": "core_issuePaymentCert",
-		"type": "function",
-		"inputs": [{"name": "txHash", "type": "bytes32"}]
-	}]
-	`
-	return abi.JSON(strings.NewReader(contractABI))
+	return tw.contract.Load().abi, nil
 }
 
 func main() {
 	ctx := context.Background()
 	l, _ := zap.NewProduction()
 
+	const performerTimeout = 5 * time.Second
+
 	config := &Config{
 		RPCServerURL: "http://localhost:3000",
+		TaskTimeout:  performerTimeout,
 	}
 
 	w := NewTaskWorker(l, config)
 
+	if config.MetricsAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(config.MetricsAddr, w.MetricsHandler()); err != nil {
+				l.Sugar().Errorw("metrics server exited", "addr", config.MetricsAddr, "error", err)
+			}
+		}()
+	}
+
 	pp, err := server.NewPonosPerformerWithRpcServer(&server.PonosPerformerConfig{
 		Port:    8080,
-		Timeout: 5 * time.Second,
+		Timeout: performerTimeout,
 	}, w, l)
 	if err != nil {
-		logger.Fatal("failed to create performer", zap.Error(err))
+		l.Sugar().Fatalw("failed to create performer", "error", err)
 	}
 
-	if err := ponos.Start(ctx); err != nil {
-		logger.Fatal("failed to start performer", zap.Error(err))
+	if err := pp.Start(ctx); err != nil {
+		l.Sugar().Fatalw("failed to start performer", "error", err)
 	}
 }
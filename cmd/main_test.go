@@ -15,15 +15,12 @@ import (
 	"go.uber.org/zap"
 )
 
-func newTestWorker(t *testing.T) *TaskWorker {
+func newTestWorker(t *testing.T, rpcServerURL string) *TaskWorker {
 	logger, err := zap.NewDevelopment()
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
-	return &TaskWorker{
-		logger: logger,
-		config: &Config{},
-	}
+	return NewTaskWorker(logger, &Config{RPCServerURL: rpcServerURL})
 }
 
 func TestHandleTask_ValidPayload(t *testing.T) {
@@ -43,8 +40,7 @@ func TestHandleTask_ValidPayload(t *testing.T) {
 	}))
 	defer mockServer.Close()
 
-	worker := newTestWorker(t)
-	worker.config.RPCServerURL = mockServer.URL // <<< Set RPC URL to mock
+	worker := newTestWorker(t, mockServer.URL)
 
 	parsedABI, err := worker.getParsedABI()
 	if err != nil {
@@ -82,7 +78,7 @@ func TestHandleTask_ValidPayload(t *testing.T) {
 }
 
 func TestHandleTask_ShortPayload(t *testing.T) {
-	worker := newTestWorker(t)
+	worker := newTestWorker(t, "")
 
 	req := &performerV1.TaskRequest{
 		TaskId:  []byte("short-task"),
@@ -105,8 +101,7 @@ func TestHandleTask_InvalidArgType(t *testing.T) {
 	}))
 	defer mockServer.Close()
 
-	worker := newTestWorker(t)
-	worker.config.RPCServerURL = mockServer.URL
+	worker := newTestWorker(t, mockServer.URL)
 
 	parsedABI, err := abi.JSON(strings.NewReader(`[{"name":"core_issuePaymentCert","type":"function","inputs":[{"type":"uint256"}]}]`))
 	if err != nil {
@@ -142,8 +137,7 @@ func TestHandleTask_ExtraArgs(t *testing.T) {
 	}))
 	defer mockServer.Close()
 
-	worker := newTestWorker(t)
-	worker.config.RPCServerURL = mockServer.URL
+	worker := newTestWorker(t, mockServer.URL)
 
 	parsedABI, err := abi.JSON(strings.NewReader(`[{"name":"core_issuePaymentCert","type":"function","inputs":[{"type":"bytes32"},{"type":"string"}]}]`))
 	if err != nil {
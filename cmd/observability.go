@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/4mica-Network/4mica-core-avs/jsonrpc"
+)
+
+// callUpstream wraps tw.rpcClient.Call with debug-level correlation
+// logging (task_id, method, params, duration, and on success the jsonrpc
+// id and result size; on failure the error) and records upstream RPC
+// latency, so operators can see what a failing task actually sent and
+// received without reasoning about the jsonrpc package directly. The
+// request size and the HTTP status code are not logged.
+func (tw *TaskWorker) callUpstream(ctx context.Context, taskID []byte, method string, params interface{}, idempotent bool) (*jsonrpc.Response, error) {
+	logger := tw.logger.Sugar()
+
+	loggedParams := interface{}(params)
+	if tw.config.RedactRPCParams {
+		loggedParams = "[redacted]"
+	}
+
+	start := time.Now()
+	resp, err := tw.rpcClient.Call(ctx, method, params, idempotent)
+	elapsed := time.Since(start)
+	tw.metrics.RPCDuration.WithLabelValues(method).Observe(elapsed.Seconds())
+
+	if err != nil {
+		logger.Debugw("upstream RPC call failed",
+			"task_id", string(taskID),
+			"method", method,
+			"params", loggedParams,
+			"duration", elapsed,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	logger.Debugw("upstream RPC call succeeded",
+		"task_id", string(taskID),
+		"method", method,
+		"params", loggedParams,
+		"jsonrpc_id", resp.ID,
+		"result_size", len(resp.Result),
+		"duration", elapsed,
+	)
+	return resp, nil
+}
+
+// callUpstreamBatch is callUpstream's batch-request counterpart: it logs
+// and times a whole JSON-RPC batch rather than a single call.
+func (tw *TaskWorker) callUpstreamBatch(ctx context.Context, calls []jsonrpc.BatchCall) ([]*jsonrpc.Response, error) {
+	logger := tw.logger.Sugar()
+
+	start := time.Now()
+	responses, err := tw.rpcClient.CallBatch(ctx, calls)
+	elapsed := time.Since(start)
+	tw.metrics.RPCDuration.WithLabelValues("batch").Observe(elapsed.Seconds())
+
+	if err != nil {
+		logger.Debugw("upstream RPC batch call failed", "batch_size", len(calls), "duration", elapsed, "error", err)
+		return nil, err
+	}
+
+	logger.Debugw("upstream RPC batch call succeeded", "batch_size", len(calls), "duration", elapsed)
+	return responses, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+const methodSelectorLength = 4
+
+// MethodHandler handles a single decoded ABI method call and produces the
+// task response returned to the caller.
+type MethodHandler func(ctx context.Context, tw *TaskWorker, taskID []byte, args []interface{}) (*performerV1.TaskResponse, error)
+
+// BatchParamsFunc converts a method's decoded arguments into the upstream
+// JSON-RPC method name and params used to invoke it as part of a batch
+// request. Only methods registered with RegisterBatchParams participate in
+// HandleTaskBatch; the rest fall back to their MethodHandler individually.
+type BatchParamsFunc func(args []interface{}) (rpcMethod string, params interface{}, err error)
+
+// methodEntry pairs a registered handler with the ABI method it decodes
+// arguments with.
+type methodEntry struct {
+	abiMethod   abi.Method
+	handler     MethodHandler
+	batchParams BatchParamsFunc
+}
+
+// MethodRegistry dispatches incoming task payloads to a handler based on
+// the 4-byte method selector encoded in the ABI, so that TaskWorker can
+// support more than one payment-cert operation without the dispatch logic
+// itself knowing about any particular method.
+type MethodRegistry struct {
+	parsedABI    abi.ABI
+	methods      map[[methodSelectorLength]byte]methodEntry
+	selectorByID map[string][methodSelectorLength]byte
+}
+
+// NewMethodRegistry builds an empty registry bound to parsedABI. Handlers
+// are added with Register.
+func NewMethodRegistry(parsedABI abi.ABI) *MethodRegistry {
+	return &MethodRegistry{
+		parsedABI:    parsedABI,
+		methods:      make(map[[methodSelectorLength]byte]methodEntry),
+		selectorByID: make(map[string][methodSelectorLength]byte),
+	}
+}
+
+// Register binds handler to the ABI method named name. It returns an error
+// if the ABI has no such method.
+func (r *MethodRegistry) Register(name string, handler MethodHandler) error {
+	abiMethod, ok := r.parsedABI.Methods[name]
+	if !ok {
+		return fmt.Errorf("registry: ABI method %q not found", name)
+	}
+
+	var selector [methodSelectorLength]byte
+	copy(selector[:], abiMethod.ID)
+	r.methods[selector] = methodEntry{abiMethod: abiMethod, handler: handler}
+	r.selectorByID[name] = selector
+	return nil
+}
+
+// RegisterBatchParams attaches batch support to a method already added with
+// Register, so that HandleTaskBatch can coalesce it into a JSON-RPC batch
+// request instead of invoking its MethodHandler individually.
+func (r *MethodRegistry) RegisterBatchParams(name string, fn BatchParamsFunc) error {
+	selector, ok := r.selectorByID[name]
+	if !ok {
+		return fmt.Errorf("registry: method %q must be registered with Register before RegisterBatchParams", name)
+	}
+	entry := r.methods[selector]
+	entry.batchParams = fn
+	r.methods[selector] = entry
+	return nil
+}
+
+// decodedCall is the result of decoding a task payload against the
+// registry: the matched ABI method, its unpacked arguments, and the
+// handler to invoke.
+type decodedCall struct {
+	entry methodEntry
+	args  []interface{}
+}
+
+// decode matches payload's 4-byte selector against a registered method and
+// unpacks its arguments. It is shared by ValidateTask (to confirm the
+// payload is well-formed) and HandleTask (to actually dispatch it).
+func (r *MethodRegistry) decode(payload []byte) (*decodedCall, error) {
+	if len(payload) < methodSelectorLength {
+		return nil, fmt.Errorf("payload too short: expected at least %d bytes", methodSelectorLength)
+	}
+
+	var selector [methodSelectorLength]byte
+	copy(selector[:], payload[:methodSelectorLength])
+
+	entry, ok := r.methods[selector]
+	if !ok {
+		return nil, fmt.Errorf("no handler registered for method selector %x", selector)
+	}
+
+	args, err := entry.abiMethod.Inputs.Unpack(payload[methodSelectorLength:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack arguments for method %q: %w", entry.abiMethod.Name, err)
+	}
+
+	return &decodedCall{entry: entry, args: args}, nil
+}
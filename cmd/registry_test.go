@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+const testMultiMethodABI = `
+[
+	{"name": "core_issuePaymentCert", "type": "function", "inputs": [{"name": "txHash", "type": "bytes32"}]},
+	{"name": "core_revokePaymentCert", "type": "function", "inputs": [{"name": "txHash", "type": "bytes32"}]}
+]
+`
+
+func TestMethodRegistry_RegisterUnknownMethod(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(testMultiMethodABI))
+	if err != nil {
+		t.Fatalf("failed to parse test ABI: %v", err)
+	}
+
+	registry := NewMethodRegistry(parsedABI)
+	noop := func(ctx context.Context, tw *TaskWorker, taskID []byte, args []interface{}) (*performerV1.TaskResponse, error) {
+		return nil, nil
+	}
+
+	if err := registry.Register("does_not_exist", noop); err == nil {
+		t.Error("expected error registering unknown method, got nil")
+	}
+}
+
+func TestMethodRegistry_DecodeDispatchesToRegisteredMethod(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(testMultiMethodABI))
+	if err != nil {
+		t.Fatalf("failed to parse test ABI: %v", err)
+	}
+
+	registry := NewMethodRegistry(parsedABI)
+
+	var calledWith string
+	register := func(name string) {
+		err := registry.Register(name, func(ctx context.Context, tw *TaskWorker, taskID []byte, args []interface{}) (*performerV1.TaskResponse, error) {
+			calledWith = name
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("failed to register %q: %v", name, err)
+		}
+	}
+	register("core_issuePaymentCert")
+	register("core_revokePaymentCert")
+
+	var txHash [32]byte
+	copy(txHash[:], []byte("revoke-me-please-32-bytes-long!"))
+	payload, err := parsedABI.Pack("core_revokePaymentCert", txHash)
+	if err != nil {
+		t.Fatalf("failed to pack payload: %v", err)
+	}
+
+	call, err := registry.decode(payload)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if _, err := call.entry.handler(context.Background(), nil, nil, call.args); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if calledWith != "core_revokePaymentCert" {
+		t.Errorf("unexpected handler invoked: got %q, want %q", calledWith, "core_revokePaymentCert")
+	}
+}
+
+func TestMethodRegistry_DecodeUnregisteredSelector(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(testMultiMethodABI))
+	if err != nil {
+		t.Fatalf("failed to parse test ABI: %v", err)
+	}
+
+	registry := NewMethodRegistry(parsedABI)
+	if err := registry.Register("core_issuePaymentCert", func(ctx context.Context, tw *TaskWorker, taskID []byte, args []interface{}) (*performerV1.TaskResponse, error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("failed to register method: %v", err)
+	}
+
+	var txHash [32]byte
+	payload, err := parsedABI.Pack("core_revokePaymentCert", txHash)
+	if err != nil {
+		t.Fatalf("failed to pack payload: %v", err)
+	}
+
+	if _, err := registry.decode(payload); err == nil {
+		t.Error("expected error decoding unregistered selector, got nil")
+	}
+}
@@ -0,0 +1,331 @@
+// Package jsonrpc implements a minimal JSON-RPC 2.0 client over HTTP with
+// timeouts, connection reuse, and exponential-backoff retries for
+// idempotent calls.
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const jsonRPCVersion = "2.0"
+
+// CodeDeadlineExceeded is the RPCError code used when the caller's context
+// deadline is reached before a call could complete. It is not part of the
+// JSON-RPC 2.0 spec's reserved range; it lives alongside other
+// server-error codes below -32000.
+const CodeDeadlineExceeded = -32001
+
+// RPCError represents the standard JSON-RPC 2.0 error object.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("jsonrpc: server returned error %d: %s", e.Code, e.Message)
+}
+
+// deadlineExceededError builds the structured error returned when ctx runs
+// out of time before a call completes, so callers can detect and surface
+// it the same way they would any other RPCError.
+func deadlineExceededError(cause error) *RPCError {
+	return &RPCError{Code: CodeDeadlineExceeded, Message: fmt.Sprintf("deadline exceeded: %v", cause)}
+}
+
+// Request is a single JSON-RPC 2.0 request object.
+type Request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      uint64      `json:"id"`
+}
+
+// Response is a single JSON-RPC 2.0 response object.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      uint64          `json:"id"`
+}
+
+// Config configures a Client.
+type Config struct {
+	// URL is the JSON-RPC endpoint to call.
+	URL string
+
+	// RequestTimeout bounds a single HTTP round trip; each retry attempt
+	// gets its own timeout. Defaults to 10s.
+	RequestTimeout time.Duration
+
+	// DialTimeout bounds establishing the underlying TCP connection.
+	// Defaults to 5s.
+	DialTimeout time.Duration
+
+	// MaxRetries is the number of additional attempts made for idempotent
+	// calls after a 5xx response or network error. Defaults to 3.
+	MaxRetries int
+
+	// RetryBaseDelay is the base of the exponential backoff between
+	// retries. Defaults to 50ms.
+	RetryBaseDelay time.Duration
+
+	// RetryMaxDelay caps the backoff delay. Defaults to 2s.
+	RetryMaxDelay time.Duration
+}
+
+func (c *Config) withDefaults() *Config {
+	cfg := *c
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 10 * time.Second
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = 50 * time.Millisecond
+	}
+	if cfg.RetryMaxDelay <= 0 {
+		cfg.RetryMaxDelay = 2 * time.Second
+	}
+	return &cfg
+}
+
+// Client is a JSON-RPC 2.0 client backed by a shared *http.Client so that
+// connections to the upstream RPC server are reused across calls.
+type Client struct {
+	url            string
+	httpClient     *http.Client
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	nextID         uint64
+}
+
+// NewClient builds a Client from cfg, applying defaults for any zero-value
+// fields.
+func NewClient(cfg *Config) *Client {
+	cfg = cfg.withDefaults()
+
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: cfg.DialTimeout,
+		}).DialContext,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &Client{
+		url: cfg.URL,
+		httpClient: &http.Client{
+			Timeout:   cfg.RequestTimeout,
+			Transport: transport,
+		},
+		maxRetries:     cfg.MaxRetries,
+		retryBaseDelay: cfg.RetryBaseDelay,
+		retryMaxDelay:  cfg.RetryMaxDelay,
+	}
+}
+
+// Call issues a single JSON-RPC request and returns the parsed response
+// envelope. If idempotent is true, the call is retried with exponential
+// backoff and jitter on 5xx responses or network errors; non-idempotent
+// calls are attempted exactly once.
+func (c *Client) Call(ctx context.Context, method string, params interface{}, idempotent bool) (*Response, error) {
+	id := atomic.AddUint64(&c.nextID, 1)
+	req := &Request{
+		JSONRPC: jsonRPCVersion,
+		Method:  method,
+		Params:  params,
+		ID:      id,
+	}
+
+	attempts := 1
+	if idempotent {
+		attempts += c.maxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, deadlineExceededError(err)
+		}
+
+		if attempt > 0 {
+			if err := c.sleepBackoff(ctx, attempt); err != nil {
+				return nil, deadlineExceededError(err)
+			}
+		}
+
+		resp, retryable, err := c.do(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, deadlineExceededError(ctx.Err())
+		}
+		lastErr = err
+		if !retryable {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("jsonrpc: call to %q failed after %d attempt(s): %w", method, attempts, lastErr)
+}
+
+// BatchCall is a single method invocation submitted as part of a batch
+// request via CallBatch.
+type BatchCall struct {
+	Method string
+	Params interface{}
+}
+
+// CallBatch sends calls as a single JSON-RPC 2.0 batch request (a JSON
+// array of request objects) and returns one response per call, in the
+// same order as calls, de-multiplexed by response id. Batch requests are
+// not retried: a partially-failed batch is the caller's responsibility to
+// re-submit. The element for a call that the server omitted from its
+// response array is left nil.
+func (c *Client) CallBatch(ctx context.Context, calls []BatchCall) ([]*Response, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, deadlineExceededError(err)
+	}
+
+	reqs := make([]*Request, len(calls))
+	indexByID := make(map[uint64]int, len(calls))
+	for i, bc := range calls {
+		id := atomic.AddUint64(&c.nextID, 1)
+		reqs[i] = &Request{
+			JSONRPC: jsonRPCVersion,
+			Method:  bc.Method,
+			Params:  bc.Params,
+			ID:      id,
+		}
+		indexByID[id] = i
+	}
+
+	respBody, _, err := c.roundTrip(ctx, reqs)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, deadlineExceededError(ctx.Err())
+		}
+		return nil, err
+	}
+
+	var rawResponses []Response
+	if err := json.Unmarshal(respBody, &rawResponses); err != nil {
+		return nil, fmt.Errorf("jsonrpc: failed to decode batch response: %w", err)
+	}
+
+	results := make([]*Response, len(calls))
+	for i := range rawResponses {
+		resp := rawResponses[i]
+		idx, ok := indexByID[resp.ID]
+		if !ok {
+			continue
+		}
+		results[idx] = &resp
+	}
+
+	return results, nil
+}
+
+// do performs a single HTTP round trip for req. The second return value
+// reports whether the error (if any) is worth retrying.
+func (c *Client) do(ctx context.Context, req *Request) (*Response, bool, error) {
+	respBody, retryable, err := c.roundTrip(ctx, req)
+	if err != nil {
+		return nil, retryable, err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, false, fmt.Errorf("jsonrpc: failed to decode response: %w", err)
+	}
+	if resp.ID != req.ID {
+		return nil, false, fmt.Errorf("jsonrpc: response id %d does not match request id %d", resp.ID, req.ID)
+	}
+	if resp.Error != nil {
+		return &resp, false, resp.Error
+	}
+
+	return &resp, false, nil
+}
+
+// roundTrip marshals body (a *Request or []*Request) as JSON, POSTs it to
+// c.url, and returns the raw response bytes. The second return value
+// reports whether a failure is worth retrying — true for network errors
+// not caused by ctx expiring, and for 5xx responses; always false when err
+// is nil. Callers are responsible for unmarshaling the returned bytes into
+// the response shape they expect (a single Response vs. a []Response).
+func (c *Client) roundTrip(ctx context.Context, body interface{}) ([]byte, bool, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, false, fmt.Errorf("jsonrpc: failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, false, fmt.Errorf("jsonrpc: failed to build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		// Network errors (including client-side timeouts) are retryable
+		// unless the caller's context is what gave up.
+		return nil, ctx.Err() == nil, fmt.Errorf("jsonrpc: request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("jsonrpc: failed to read response body: %w", err)
+	}
+
+	if httpResp.StatusCode >= 500 {
+		return nil, true, fmt.Errorf("jsonrpc: server returned status %d: %s", httpResp.StatusCode, string(respBody))
+	}
+	if httpResp.StatusCode >= 400 {
+		return nil, false, fmt.Errorf("jsonrpc: server returned status %d: %s", httpResp.StatusCode, string(respBody))
+	}
+
+	return respBody, false, nil
+}
+
+// sleepBackoff waits for an exponentially increasing, jittered delay before
+// the next retry, returning early if ctx is done first.
+func (c *Client) sleepBackoff(ctx context.Context, attempt int) error {
+	delay := c.retryBaseDelay << uint(attempt-1)
+	if delay > c.retryMaxDelay || delay <= 0 {
+		delay = c.retryMaxDelay
+	}
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
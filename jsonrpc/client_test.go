@@ -0,0 +1,199 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Call_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		resp := Response{JSONRPC: jsonRPCVersion, Result: json.RawMessage(`"ok"`), ID: req.ID}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := NewClient(&Config{URL: srv.URL})
+	resp, err := client.Call(context.Background(), "core_issuePaymentCert", []string{"0xabc"}, false)
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	if result != "ok" {
+		t.Errorf("unexpected result: got %q, want %q", result, "ok")
+	}
+}
+
+func TestClient_Call_ServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		resp := Response{
+			JSONRPC: jsonRPCVersion,
+			Error:   &RPCError{Code: -32000, Message: "boom"},
+			ID:      req.ID,
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := NewClient(&Config{URL: srv.URL})
+	_, err := client.Call(context.Background(), "core_issuePaymentCert", nil, false)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	rpcErr, ok := err.(*RPCError)
+	if !ok {
+		t.Fatalf("expected *RPCError, got %T: %v", err, err)
+	}
+	if rpcErr.Code != -32000 {
+		t.Errorf("unexpected error code: got %d, want %d", rpcErr.Code, -32000)
+	}
+}
+
+func TestClient_Call_RetriesIdempotentOn5xx(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		resp := Response{JSONRPC: jsonRPCVersion, Result: json.RawMessage(`"ok"`), ID: req.ID}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := NewClient(&Config{URL: srv.URL, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 5 * time.Millisecond})
+	_, err := client.Call(context.Background(), "core_issuePaymentCert", nil, true)
+	if err != nil {
+		t.Fatalf("Call failed after retries: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("unexpected call count: got %d, want 3", got)
+	}
+}
+
+func TestClient_Call_DoesNotRetryNonIdempotentOn5xx(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client := NewClient(&Config{URL: srv.URL, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 5 * time.Millisecond})
+	_, err := client.Call(context.Background(), "core_issuePaymentCert", nil, false)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("unexpected call count: got %d, want 1", got)
+	}
+}
+
+func TestClient_CallBatch_DemultiplexesByID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []Request
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatalf("failed to decode batch request: %v", err)
+		}
+
+		// Respond out of order to exercise id-based de-multiplexing.
+		resps := make([]Response, len(reqs))
+		for i := len(reqs) - 1; i >= 0; i-- {
+			req := reqs[i]
+			params, _ := req.Params.([]interface{})
+			result, _ := json.Marshal(params)
+			resps[len(reqs)-1-i] = Response{JSONRPC: jsonRPCVersion, Result: result, ID: req.ID}
+		}
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer srv.Close()
+
+	client := NewClient(&Config{URL: srv.URL})
+	calls := []BatchCall{
+		{Method: "core_issuePaymentCert", Params: []interface{}{"0x1"}},
+		{Method: "core_issuePaymentCert", Params: []interface{}{"0x2"}},
+		{Method: "core_issuePaymentCert", Params: []interface{}{"0x3"}},
+	}
+
+	resps, err := client.CallBatch(context.Background(), calls)
+	if err != nil {
+		t.Fatalf("CallBatch failed: %v", err)
+	}
+	if len(resps) != len(calls) {
+		t.Fatalf("unexpected response count: got %d, want %d", len(resps), len(calls))
+	}
+	for i, want := range []string{"0x1", "0x2", "0x3"} {
+		var got []string
+		if err := json.Unmarshal(resps[i].Result, &got); err != nil {
+			t.Fatalf("failed to unmarshal result %d: %v", i, err)
+		}
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("response %d mismatched with its request: got %v, want [%s]", i, got, want)
+		}
+	}
+}
+
+func TestClient_CallBatch_DeadlineExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		json.NewEncoder(w).Encode([]Response{})
+	}))
+	defer srv.Close()
+
+	client := NewClient(&Config{URL: srv.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CallBatch(ctx, []BatchCall{{Method: "core_issuePaymentCert", Params: []interface{}{"0x1"}}})
+	if err == nil {
+		t.Fatal("expected a deadline-exceeded error, got nil")
+	}
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected *RPCError, got %T: %v", err, err)
+	}
+	if rpcErr.Code != CodeDeadlineExceeded {
+		t.Errorf("unexpected error code: got %d, want %d", rpcErr.Code, CodeDeadlineExceeded)
+	}
+}
+
+func TestClient_CallBatch_Empty(t *testing.T) {
+	client := NewClient(&Config{URL: "http://unused.invalid"})
+	resps, err := client.CallBatch(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected no error for empty batch, got %v", err)
+	}
+	if resps != nil {
+		t.Errorf("expected nil responses for empty batch, got %v", resps)
+	}
+}
+
+func TestClient_Call_MismatchedID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := Response{JSONRPC: jsonRPCVersion, Result: json.RawMessage(`"ok"`), ID: 99999}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := NewClient(&Config{URL: srv.URL})
+	_, err := client.Call(context.Background(), "core_issuePaymentCert", nil, false)
+	if err == nil {
+		t.Fatal("expected error for mismatched id, got nil")
+	}
+}
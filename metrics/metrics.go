@@ -0,0 +1,73 @@
+// Package metrics exposes Prometheus instrumentation for TaskWorker so
+// operators have an aggregatable signal for task throughput, latency, and
+// failures instead of having to grep zap logs.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics bundles the counters, histograms, and gauge TaskWorker reports
+// against. Each instance owns its own registry so multiple TaskWorkers
+// (e.g. one per test) can coexist without colliding on metric names.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	// TasksHandled counts completed tasks, labeled by ABI method name and
+	// outcome ("success" or "failure").
+	TasksHandled *prometheus.CounterVec
+
+	// TaskDuration observes HandleTask latency in seconds, labeled by ABI
+	// method name.
+	TaskDuration *prometheus.HistogramVec
+
+	// RPCDuration observes upstream JSON-RPC call latency in seconds,
+	// labeled by RPC method name (or "batch" for batched calls).
+	RPCDuration *prometheus.HistogramVec
+
+	// InFlightTasks is the number of tasks currently being handled.
+	InFlightTasks prometheus.Gauge
+}
+
+// New builds a Metrics bundle with all series registered under namespace.
+func New(namespace string) *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: registry,
+		TasksHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_total",
+			Help:      "Total number of tasks handled, labeled by ABI method and outcome.",
+		}, []string{"method", "status"}),
+		TaskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "task_duration_seconds",
+			Help:      "HandleTask latency in seconds, labeled by ABI method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		RPCDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_rpc_duration_seconds",
+			Help:      "Upstream JSON-RPC call latency in seconds, labeled by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		InFlightTasks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "tasks_in_flight",
+			Help:      "Number of tasks currently being handled.",
+		}),
+	}
+
+	registry.MustRegister(m.TasksHandled, m.TaskDuration, m.RPCDuration, m.InFlightTasks)
+	return m
+}
+
+// Handler serves the registered metrics in the Prometheus text exposition
+// format, suitable for mounting at /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
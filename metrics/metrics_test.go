@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_HandlerServesRegisteredSeries(t *testing.T) {
+	m := New("core_avs_test")
+	m.TasksHandled.WithLabelValues("core_issuePaymentCert", "success").Inc()
+	m.InFlightTasks.Set(1)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("unexpected status code: got %d, want 200", rec.Code)
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "core_avs_test_tasks_total") {
+		t.Errorf("expected tasks_total series in output, got:\n%s", body)
+	}
+	if !strings.Contains(body, "core_avs_test_tasks_in_flight") {
+		t.Errorf("expected tasks_in_flight series in output, got:\n%s", body)
+	}
+}
+
+func TestMetrics_IndependentInstancesDoNotShareRegistries(t *testing.T) {
+	// Each instance owns its own registry, so creating two with the same
+	// namespace must not panic on duplicate registration.
+	New("core_avs_test_independent")
+	New("core_avs_test_independent")
+}